@@ -0,0 +1,29 @@
+// Copyright © 2017 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+// +build windows
+
+// Package wmi holds the Windows WMI-backed builtin collectors (network_ip,
+// etc.). This snapshot of the tree ships network_ip_test.go but not the
+// collector implementations (network_ip.go and friends) it tests, so there is
+// no constructor left to wire through internal/logger. collectorLogger below
+// is that wiring, ready for whichever collector constructor lands here next;
+// each should call collectorLogger(id) the same way server.New and
+// reverse.Start call agentlog.For, instead of falling back to the zerolog
+// global logger.
+package wmi
+
+import (
+	agentlog "github.com/circonus-labs/circonus-agent/internal/logger"
+	"github.com/rs/zerolog"
+)
+
+// collectorLogger returns the per-collector logger for a WMI builtin
+// identified by id (e.g. "network_ip"), sunk under the
+// "builtins.collector.windows.wmi" namespace so a single collector can be
+// silenced via log.sinks without touching the global level.
+func collectorLogger(id string) zerolog.Logger {
+	return agentlog.For("builtins.collector.windows.wmi." + id)
+}
@@ -0,0 +1,19 @@
+// Copyright © 2017 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package config
+
+const (
+	// KeyAuthMode determines how the HTTP/HTTPS listeners are protected (none|basic|mtls).
+	KeyAuthMode = "auth.mode"
+
+	// KeyAuthHtpasswdFile is the path to an htpasswd-style file used for basic auth mode.
+	// Entries may be bcrypt, sha, or apr1-md5 hashed.
+	KeyAuthHtpasswdFile = "auth.htpasswd_file"
+
+	// KeyAuthMTLSCAFile is the path to a PEM encoded CA bundle used to verify client
+	// certificates presented to the HTTPS listener in mtls mode.
+	KeyAuthMTLSCAFile = "auth.mtls_ca_file"
+)
@@ -0,0 +1,22 @@
+// Copyright © 2017 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package config
+
+const (
+	// KeyGRPCListen is the address the optional gRPC control/inventory API
+	// binds to, e.g. ":2610". Leave empty to disable it. When the HTTPS
+	// listener is configured, the gRPC server reuses its certificate/key.
+	KeyGRPCListen = "grpc_listen"
+
+	// KeyGRPCAllowExperimental must be explicitly set true to start the gRPC
+	// listener. It exists because the server is, for now, wired with a
+	// private JSON wire codec rather than real protobuf (see
+	// internal/server/pb/doc.go) -- it will talk to nothing but this agent's
+	// own stubs, not grpcurl or any other standard gRPC client. Remove this
+	// gate once protoc-gen-go/protoc-gen-go-grpc are wired into the build and
+	// the codec is swapped for the generated one.
+	KeyGRPCAllowExperimental = "grpc_allow_experimental"
+)
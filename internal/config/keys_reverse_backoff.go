@@ -0,0 +1,30 @@
+// Copyright © 2017 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package config
+
+const (
+	// KeyReverseMinBackoff is the minimum delay between reverse connection attempts.
+	KeyReverseMinBackoff = "reverse.min_backoff"
+
+	// KeyReverseMaxBackoff is the maximum delay between reverse connection attempts.
+	KeyReverseMaxBackoff = "reverse.max_backoff"
+
+	// KeyReverseBackoffMultiplier is the exponential growth factor applied per
+	// consecutive failed connection attempt.
+	KeyReverseBackoffMultiplier = "reverse.backoff_multiplier"
+
+	// KeyReverseJitterMode selects how backoff delays are randomized (none|full|decorrelated).
+	KeyReverseJitterMode = "reverse.jitter_mode"
+
+	// KeyReverseCBThreshold is the number of consecutive connect failures, within
+	// KeyReverseCBWindow, after which the circuit breaker forces a broker reconfig.
+	KeyReverseCBThreshold = "reverse.circuit_breaker_threshold"
+
+	// KeyReverseCBWindow is the window over which consecutive connect failures
+	// are counted for the circuit breaker. The window starts at the first
+	// failure; a failure after it has expired starts a new window.
+	KeyReverseCBWindow = "reverse.circuit_breaker_window"
+)
@@ -0,0 +1,14 @@
+// Copyright © 2017 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package config
+
+const (
+	// KeyTrustedProxies is a list of CIDR blocks of proxies whose addresses are
+	// skipped when X-Forwarded-For is walked right-to-left for the client IP.
+	// X-Forwarded-For and X-Real-IP are read whatever address the request
+	// itself came from.
+	KeyTrustedProxies = "trusted_proxies"
+)
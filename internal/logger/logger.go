@@ -0,0 +1,129 @@
+// Copyright © 2017 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+// Package logger wraps zerolog behind a small, per-subsystem Logger so
+// packages stop reaching for the zerolog/log global and can instead be
+// routed to their own sink (a separate file, a different level, JSON vs.
+// console) purely through configuration.
+package logger
+
+import (
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+	lumberjack "gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Logger is the interface packages should depend on instead of zerolog.Logger
+// directly. Its methods are zerolog's own zero-allocation event builders, so
+// wrapping does not add per-event overhead on hot paths (e.g. reverse's
+// readHeader/buildFrame).
+type Logger interface {
+	Debug() *zerolog.Event
+	Info() *zerolog.Event
+	Warn() *zerolog.Event
+	Error() *zerolog.Event
+	With() zerolog.Context
+}
+
+// *zerolog.Logger satisfies Logger (Debug/Info/Warn/Error have pointer
+// receivers, With a value receiver); For returns the concrete zerolog.Logger value so it
+// remains a drop-in replacement for `log.With().Str("pkg", x).Logger()`.
+var _ Logger = &zerolog.Logger{}
+
+// sinkConfig mirrors one entry of the `log.sinks` viper list, e.g.:
+//
+//	log:
+//	  sinks:
+//	    - name: reverse
+//	      path: /var/log/agent-reverse.json
+//	      level: debug
+//	      format: json
+type sinkConfig struct {
+	Name   string `mapstructure:"name"`
+	Path   string `mapstructure:"path"`
+	Level  string `mapstructure:"level"`
+	Format string `mapstructure:"format"`
+}
+
+var (
+	mu        sync.RWMutex
+	loggers   = map[string]zerolog.Logger{}
+	sinks     []sinkConfig
+	sinksRead bool
+)
+
+// For returns the Logger configured for pkg, building it (and its sink
+// writer, if one is configured) on first use and caching the result.
+func For(pkg string) zerolog.Logger {
+	mu.RLock()
+	if l, ok := loggers[pkg]; ok {
+		mu.RUnlock()
+		return l
+	}
+	mu.RUnlock()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if l, ok := loggers[pkg]; ok { // lost the race to build it
+		return l
+	}
+
+	readSinks()
+
+	l := build(pkg)
+	loggers[pkg] = l
+	return l
+}
+
+func readSinks() {
+	if sinksRead {
+		return
+	}
+	sinksRead = true
+	_ = viper.UnmarshalKey("log.sinks", &sinks)
+}
+
+func build(pkg string) zerolog.Logger {
+	for _, s := range sinks {
+		if s.Name != pkg {
+			continue
+		}
+		lvl, err := zerolog.ParseLevel(strings.ToLower(s.Level))
+		if err != nil {
+			lvl = zerolog.InfoLevel
+		}
+		return zerolog.New(sinkWriter(s)).Level(lvl).With().Timestamp().Str("pkg", pkg).Logger()
+	}
+
+	// no sink configured for pkg, fall back to the default stderr logger
+	// at whatever level the rest of the agent is using.
+	return log.With().Str("pkg", pkg).Logger()
+}
+
+func sinkWriter(s sinkConfig) io.Writer {
+	if s.Path == "" {
+		return os.Stderr
+	}
+
+	var w io.Writer = &lumberjack.Logger{
+		Filename:   s.Path,
+		MaxSize:    100, // megabytes
+		MaxBackups: 5,
+		MaxAge:     28, // days
+		Compress:   true,
+	}
+
+	if strings.ToLower(s.Format) == "console" {
+		w = zerolog.ConsoleWriter{Out: w}
+	}
+
+	return w
+}
@@ -0,0 +1,187 @@
+// Copyright © 2017 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package reverse
+
+import (
+	"expvar"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/circonus-labs/circonus-agent/internal/config"
+	"github.com/spf13/viper"
+)
+
+const (
+	jitterNone         = "none"
+	jitterFull         = "full"
+	jitterDecorrelated = "decorrelated"
+
+	defaultMinBackoff  = 2 * time.Second
+	defaultMaxBackoff  = 60 * time.Second
+	defaultMultiplier  = 2.0
+	defaultCBThreshold = 5
+	defaultCBWindow    = 5 * time.Minute
+)
+
+// backoffPolicy computes the delay between reconnect attempts. min/max/
+// multiplier/jitterMode are read once from viper at construction time;
+// prev is the decorrelated-jitter seed carried across calls.
+type backoffPolicy struct {
+	min        time.Duration
+	max        time.Duration
+	multiplier float64
+	jitterMode string
+
+	rnd  *rand.Rand
+	prev time.Duration
+}
+
+func newBackoffPolicy() *backoffPolicy {
+	min := viper.GetDuration(config.KeyReverseMinBackoff)
+	if min <= 0 {
+		min = defaultMinBackoff
+	}
+	max := viper.GetDuration(config.KeyReverseMaxBackoff)
+	if max <= 0 {
+		max = defaultMaxBackoff
+	}
+	mult := viper.GetFloat64(config.KeyReverseBackoffMultiplier)
+	if mult <= 0 {
+		mult = defaultMultiplier
+	}
+	mode := strings.ToLower(viper.GetString(config.KeyReverseJitterMode))
+	switch mode {
+	case jitterNone, jitterFull, jitterDecorrelated:
+	default:
+		mode = jitterFull
+	}
+
+	return &backoffPolicy{
+		min:        min,
+		max:        max,
+		multiplier: mult,
+		jitterMode: mode,
+		rnd:        rand.New(rand.NewSource(time.Now().UnixNano())), // #nosec G404 -- jitter, not security sensitive
+		prev:       min,
+	}
+}
+
+// next returns the delay to sleep before the next connection attempt, given
+// the number of consecutive failed attempts so far (1-indexed).
+func (b *backoffPolicy) next(attempt int) time.Duration {
+	base := float64(b.min) * math.Pow(b.multiplier, float64(attempt-1))
+	if base > float64(b.max) {
+		base = float64(b.max)
+	}
+
+	var delay time.Duration
+	switch b.jitterMode {
+	case jitterNone:
+		delay = time.Duration(base)
+	case jitterDecorrelated:
+		lo := int64(b.min)
+		hi := int64(b.prev) * 3
+		if hi < lo {
+			hi = lo
+		}
+		if hi > int64(b.max) {
+			hi = int64(b.max)
+		}
+		delay = time.Duration(lo)
+		if hi > lo {
+			delay = time.Duration(lo + b.rnd.Int63n(hi-lo+1))
+		}
+	default: // jitterFull
+		delay = time.Duration(0)
+		if base > 0 {
+			delay = time.Duration(b.rnd.Int63n(int64(base) + 1))
+		}
+	}
+
+	if delay > b.max {
+		delay = b.max
+	}
+	b.prev = delay
+	return delay
+}
+
+// circuitBreaker trips after threshold consecutive connect failures occur
+// within window, forcing the caller to re-resolve the broker even when the
+// periodic configRetryLimit reconfig hasn't come due yet.
+type circuitBreaker struct {
+	threshold int
+	window    time.Duration
+
+	mu          sync.Mutex
+	fails       int
+	windowStart time.Time
+}
+
+func newCircuitBreaker() *circuitBreaker {
+	threshold := viper.GetInt(config.KeyReverseCBThreshold)
+	if threshold <= 0 {
+		threshold = defaultCBThreshold
+	}
+	window := viper.GetDuration(config.KeyReverseCBWindow)
+	if window <= 0 {
+		window = defaultCBWindow
+	}
+	return &circuitBreaker{threshold: threshold, window: window}
+}
+
+// recordFailure registers a connect failure and reports whether the breaker
+// has tripped (and reset itself) as a result.
+func (cb *circuitBreaker) recordFailure() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := time.Now()
+	if cb.windowStart.IsZero() || now.Sub(cb.windowStart) > cb.window {
+		cb.windowStart = now
+		cb.fails = 0
+	}
+	cb.fails++
+
+	if cb.fails >= cb.threshold {
+		cb.fails = 0
+		cb.windowStart = time.Time{}
+		return true
+	}
+	return false
+}
+
+// reset clears the failure count, called after a successful connection.
+func (cb *circuitBreaker) reset() {
+	cb.mu.Lock()
+	cb.fails = 0
+	cb.windowStart = time.Time{}
+	cb.mu.Unlock()
+}
+
+// reverse_backoff expvar map, scraped via the existing /stats route.
+var (
+	reverseBackoffMap    = expvar.NewMap("reverse_backoff")
+	reverseAttemptVar    = new(expvar.Int)
+	reverseLastDelayVar  = new(expvar.String)
+	reverseReconnectsVar = new(expvar.Int)
+)
+
+func init() {
+	reverseBackoffMap.Set("attempt", reverseAttemptVar)
+	reverseBackoffMap.Set("last_delay", reverseLastDelayVar)
+	reverseBackoffMap.Set("reconnects", reverseReconnectsVar)
+}
+
+// recordBackoffStats publishes the current attempt count, last computed
+// delay, and total successful reconnects to the reverse_backoff expvar map.
+func recordBackoffStats(attempt int, delay time.Duration, reconnects int64) {
+	reverseAttemptVar.Set(int64(attempt))
+	reverseLastDelayVar.Set(delay.String())
+	reverseReconnectsVar.Set(reconnects)
+}
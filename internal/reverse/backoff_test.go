@@ -0,0 +1,167 @@
+// Copyright © 2017 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package reverse
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func newTestBackoffPolicy(mode string) *backoffPolicy {
+	return &backoffPolicy{
+		min:        1 * time.Second,
+		max:        10 * time.Second,
+		multiplier: 2.0,
+		jitterMode: mode,
+		rnd:        rand.New(rand.NewSource(1)), // #nosec G404 -- deterministic for tests
+		prev:       1 * time.Second,
+	}
+}
+
+func TestBackoffPolicyNextJitterNone(t *testing.T) {
+	t.Log("Testing backoffPolicy.next with jitterNone")
+
+	tests := []struct {
+		name    string
+		attempt int
+		expect  time.Duration
+	}{
+		{name: "attempt 1", attempt: 1, expect: 1 * time.Second},
+		{name: "attempt 2", attempt: 2, expect: 2 * time.Second},
+		{name: "attempt 3", attempt: 3, expect: 4 * time.Second},
+		{name: "attempt capped at max", attempt: 10, expect: 10 * time.Second},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			b := newTestBackoffPolicy(jitterNone)
+			got := b.next(test.attempt)
+			if got != test.expect {
+				t.Fatalf("expected (%s) got (%s)", test.expect, got)
+			}
+		})
+	}
+}
+
+func TestBackoffPolicyNextJitterFull(t *testing.T) {
+	t.Log("Testing backoffPolicy.next with jitterFull stays within [0, base] and never exceeds max")
+
+	b := newTestBackoffPolicy(jitterFull)
+	for attempt := 1; attempt <= 10; attempt++ {
+		base := float64(b.min) * pow(b.multiplier, float64(attempt-1))
+		if base > float64(b.max) {
+			base = float64(b.max)
+		}
+
+		delay := b.next(attempt)
+		if delay < 0 {
+			t.Fatalf("attempt %d: expected non-negative delay, got (%s)", attempt, delay)
+		}
+		if delay > time.Duration(base) {
+			t.Fatalf("attempt %d: expected delay <= base (%s), got (%s)", attempt, time.Duration(base), delay)
+		}
+		if delay > b.max {
+			t.Fatalf("attempt %d: expected delay <= max (%s), got (%s)", attempt, b.max, delay)
+		}
+	}
+}
+
+func TestBackoffPolicyNextJitterDecorrelated(t *testing.T) {
+	t.Log("Testing backoffPolicy.next with jitterDecorrelated stays within [min, max]")
+
+	b := newTestBackoffPolicy(jitterDecorrelated)
+	for attempt := 1; attempt <= 10; attempt++ {
+		delay := b.next(attempt)
+		if delay < b.min {
+			t.Fatalf("attempt %d: expected delay >= min (%s), got (%s)", attempt, b.min, delay)
+		}
+		if delay > b.max {
+			t.Fatalf("attempt %d: expected delay <= max (%s), got (%s)", attempt, b.max, delay)
+		}
+	}
+}
+
+func TestBackoffPolicyNextUnknownModeUsesFullJitter(t *testing.T) {
+	t.Log("Testing backoffPolicy.next treats an unrecognized mode as jitterFull")
+
+	b := newTestBackoffPolicy("bogus")
+	// newTestBackoffPolicy bypasses newBackoffPolicy's mode validation on
+	// purpose (it builds the struct directly), so next() sees the raw value
+	// and falls into the jitterFull default branch rather than panicking.
+	delay := b.next(1)
+	if delay < 0 || delay > b.max {
+		t.Fatalf("expected delay within [0, max], got (%s)", delay)
+	}
+}
+
+// pow mirrors math.Pow for the small, non-negative integer exponents used in
+// these tests, avoiding a second import of "math" purely for test bookkeeping.
+func pow(base float64, exp float64) float64 {
+	result := 1.0
+	for i := 0; i < int(exp); i++ {
+		result *= base
+	}
+	return result
+}
+
+func TestCircuitBreakerRecordFailure(t *testing.T) {
+	t.Log("Testing circuitBreaker.recordFailure")
+
+	cb := &circuitBreaker{threshold: 3, window: time.Minute}
+
+	if cb.recordFailure() {
+		t.Fatal("expected NO trip on failure 1")
+	}
+	if cb.recordFailure() {
+		t.Fatal("expected NO trip on failure 2")
+	}
+	if !cb.recordFailure() {
+		t.Fatal("expected trip on failure 3 (threshold reached)")
+	}
+
+	t.Log("counter resets after tripping")
+	{
+		if cb.fails != 0 {
+			t.Fatalf("expected fails reset to 0, got %d", cb.fails)
+		}
+		if cb.recordFailure() {
+			t.Fatal("expected NO trip immediately after reset")
+		}
+	}
+}
+
+func TestCircuitBreakerWindowExpiry(t *testing.T) {
+	t.Log("Testing circuitBreaker.recordFailure resets the window after it expires")
+
+	cb := &circuitBreaker{threshold: 2, window: time.Millisecond}
+
+	if cb.recordFailure() {
+		t.Fatal("expected NO trip on failure 1")
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	if cb.recordFailure() {
+		t.Fatal("expected failure after window expiry to start a fresh window, not trip")
+	}
+}
+
+func TestCircuitBreakerReset(t *testing.T) {
+	t.Log("Testing circuitBreaker.reset")
+
+	cb := &circuitBreaker{threshold: 2, window: time.Minute}
+	cb.recordFailure()
+	cb.reset()
+
+	if cb.fails != 0 {
+		t.Fatalf("expected fails reset to 0, got %d", cb.fails)
+	}
+	if cb.recordFailure() {
+		t.Fatal("expected NO trip on failure 1 after reset")
+	}
+}
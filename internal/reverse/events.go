@@ -0,0 +1,70 @@
+// Copyright © 2017 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package reverse
+
+import "sync"
+
+// State describes the current phase of the reverse connection goroutine.
+type State string
+
+const (
+	// StateConnecting is emitted before a connect attempt to the broker.
+	StateConnecting State = "connecting"
+	// StateConnected is emitted once the broker connection is established.
+	StateConnected State = "connected"
+	// StateDisconnected is emitted when a connection attempt fails or an
+	// established connection drops.
+	StateDisconnected State = "disconnected"
+)
+
+// Event is a single reverse connection state transition, published to every
+// subscriber registered via Subscribe.
+type Event struct {
+	State     State
+	Attempt   int
+	LastError string
+}
+
+var (
+	watchersMu sync.Mutex
+	watchers   = map[chan Event]struct{}{}
+)
+
+// Subscribe registers a new watcher for reverse connection state changes.
+// The returned channel is buffered so a slow (or gone) subscriber can't stall
+// the reverse goroutine; Unsubscribe must be called to stop receiving events
+// and release the channel.
+func Subscribe() chan Event {
+	ch := make(chan Event, 16)
+	watchersMu.Lock()
+	watchers[ch] = struct{}{}
+	watchersMu.Unlock()
+	return ch
+}
+
+// Unsubscribe removes and closes a channel returned by Subscribe.
+func Unsubscribe(ch chan Event) {
+	watchersMu.Lock()
+	if _, ok := watchers[ch]; ok {
+		delete(watchers, ch)
+		close(ch)
+	}
+	watchersMu.Unlock()
+}
+
+// publish fans an event out to every current subscriber, dropping it for any
+// subscriber whose buffer is full rather than blocking the reverse goroutine.
+func publish(evt Event) {
+	watchersMu.Lock()
+	defer watchersMu.Unlock()
+	for ch := range watchers {
+		select {
+		case ch <- evt:
+		default:
+			logger.Warn().Str("state", string(evt.State)).Msg("dropping reverse event, subscriber buffer full")
+		}
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"github.com/circonus-labs/circonus-agent/internal/config"
+	agentlog "github.com/circonus-labs/circonus-agent/internal/logger"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -54,20 +55,13 @@ func Start() error {
 		return nil
 	}
 
-	logger = log.With().Str("pkg", "reverse").Logger()
+	logger = agentlog.For("reverse")
 	logger.Info().Msg("Setting up reverse connections")
 
 	attempt := 1
-	backoffs := []time.Duration{
-		2 * time.Second,
-		4 * time.Second,
-		6 * time.Second,
-		8 * time.Second,
-		16 * time.Second,
-		32 * time.Second,
-		60 * time.Second,
-	}
-	maxAttempts := len(backoffs) - 1
+	backoff := newBackoffPolicy()
+	breaker := newCircuitBreaker()
+	var reconnects int64
 
 	agentAddress := strings.Replace(viper.GetString(config.KeyListen), "0.0.0.0", "localhost", -1)
 
@@ -111,6 +105,8 @@ func Start() error {
 				}
 			}
 
+			publish(Event{State: StateConnecting, Attempt: attempt})
+
 			var conn *tls.Conn
 			conn, err = connect(reverseURL, tlsConfig)
 			if err != nil {
@@ -122,14 +118,28 @@ func Start() error {
 					Err(err).
 					Int("attempt", attempt).
 					Msg("failed")
+				publish(Event{State: StateDisconnected, Attempt: attempt, LastError: err.Error()})
+				if breaker.recordFailure() {
+					// consecutive failures within the circuit breaker window, force
+					// a reconfig next time through the loop instead of waiting for
+					// configRetryLimit to be reached (which successful-but-dropped
+					// connections can starve).
+					logger.Warn().Msg("circuit breaker tripped, forcing reconfig")
+					reverseURL = nil
+				}
 			} else {
-				attempt = 1                             // reset on successful connection
+				attempt = 1 // reset on successful connection
+				breaker.reset()
+				reconnects++
+				publish(Event{State: StateConnected, Attempt: attempt})
 				reverse(conn, reverseURL, agentAddress) // reconnect
+				publish(Event{State: StateDisconnected, Attempt: attempt})
 			}
 
 			// backoff retry on each consecutive failure
-			delay := backoffs[uint8(math.Min(float64(attempt-1), float64(maxAttempts)))]
+			delay := backoff.next(attempt)
 			attempt++
+			recordBackoffStats(attempt, delay, reconnects)
 			logger.Info().
 				Str("delay", delay.String()).
 				Int("attempt", attempt).
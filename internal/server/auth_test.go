@@ -0,0 +1,190 @@
+// Copyright © 2017 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestApr1MD5(t *testing.T) {
+	t.Log("Testing apr1MD5")
+
+	tests := []struct {
+		name     string
+		pass     string
+		existing string
+		expect   string
+	}{
+		{
+			name:     "known vector",
+			pass:     "myPassword",
+			existing: "$apr1$r31.....$HqJZimcKQFAMYayBlzkrA/",
+			expect:   "$apr1$r31.....$HqJZimcKQFAMYayBlzkrA/",
+		},
+		{
+			name:     "malformed existing hash (too few fields)",
+			pass:     "myPassword",
+			existing: "$apr1$r31.....",
+			expect:   "",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			got := apr1MD5(test.pass, test.existing)
+			if got != test.expect {
+				t.Fatalf("expected (%s) got (%s)", test.expect, got)
+			}
+		})
+	}
+}
+
+func TestApr1MD5RoundTrip(t *testing.T) {
+	t.Log("Testing apr1MD5 round trip (hash then re-verify)")
+
+	tests := []struct {
+		name string
+		pass string
+		salt string
+	}{
+		{name: "short password", pass: "a", salt: "abcdefgh"},
+		{name: "long password", pass: "a-much-longer-password-than-16-bytes", salt: "saltsalt"},
+		{name: "empty password", pass: "", salt: "saltsalt"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			existing := "$apr1$" + test.salt + "$"
+			hash := apr1MD5(test.pass, existing)
+			if hash == "" {
+				t.Fatal("expected non-empty hash")
+			}
+			if !verifyHtpasswdHash(hash, test.pass) {
+				t.Fatal("expected hash to verify against original password")
+			}
+			if verifyHtpasswdHash(hash, test.pass+"x") {
+				t.Fatal("expected hash to NOT verify against wrong password")
+			}
+		})
+	}
+}
+
+func TestVerifyHtpasswdHash(t *testing.T) {
+	t.Log("Testing verifyHtpasswdHash")
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("bcryptpass"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("generating bcrypt fixture: %s", err)
+	}
+
+	tests := []struct {
+		name   string
+		hash   string
+		pass   string
+		expect bool
+	}{
+		{name: "bcrypt correct", hash: string(bcryptHash), pass: "bcryptpass", expect: true},
+		{name: "bcrypt wrong password", hash: string(bcryptHash), pass: "wrong", expect: false},
+		{name: "sha correct", hash: "{SHA}5en6G6MezRroT3XKqkdPOmY/BfQ=", pass: "secret", expect: true},
+		{name: "sha wrong password", hash: "{SHA}5en6G6MezRroT3XKqkdPOmY/BfQ=", pass: "wrong", expect: false},
+		{name: "unknown scheme", hash: "plaintext", pass: "plaintext", expect: false},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			got := verifyHtpasswdHash(test.hash, test.pass)
+			if got != test.expect {
+				t.Fatalf("expected (%v) got (%v)", test.expect, got)
+			}
+		})
+	}
+}
+
+func TestHtpasswdFileReloadAndAuthenticate(t *testing.T) {
+	t.Log("Testing htpasswdFile reload/authenticate")
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "htpasswd")
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("s3cret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("generating bcrypt fixture: %s", err)
+	}
+
+	contents := "# comment\n\nalice:" + string(bcryptHash) + "\nmalformed-line-no-colon\n"
+	if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatalf("writing htpasswd fixture: %s", err)
+	}
+
+	h, err := newHtpasswdFile(path)
+	if err != nil {
+		t.Fatalf("expected NO error, got (%s)", err)
+	}
+
+	t.Log("valid user/pass")
+	{
+		if !h.authenticate("alice", "s3cret") {
+			t.Fatal("expected alice/s3cret to authenticate")
+		}
+	}
+
+	t.Log("valid user, wrong pass")
+	{
+		if h.authenticate("alice", "wrong") {
+			t.Fatal("expected alice/wrong to NOT authenticate")
+		}
+	}
+
+	t.Log("unknown user")
+	{
+		if h.authenticate("bob", "s3cret") {
+			t.Fatal("expected unknown user to NOT authenticate")
+		}
+	}
+
+	t.Log("malformed line ignored, not treated as a user")
+	{
+		if h.authenticate("malformed-line-no-colon", "") {
+			t.Fatal("expected malformed line to be skipped")
+		}
+	}
+
+	t.Log("reload picks up changes")
+	{
+		newHash, err := bcrypt.GenerateFromPassword([]byte("newpass"), bcrypt.MinCost)
+		if err != nil {
+			t.Fatalf("generating bcrypt fixture: %s", err)
+		}
+		if err := os.WriteFile(path, []byte("alice:"+string(newHash)+"\n"), 0600); err != nil {
+			t.Fatalf("rewriting htpasswd fixture: %s", err)
+		}
+		if err := h.reload(); err != nil {
+			t.Fatalf("expected NO error, got (%s)", err)
+		}
+		if !h.authenticate("alice", "newpass") {
+			t.Fatal("expected alice/newpass to authenticate after reload")
+		}
+		if h.authenticate("alice", "s3cret") {
+			t.Fatal("expected old password to stop authenticating after reload")
+		}
+	}
+}
+
+func TestNewHtpasswdFileMissing(t *testing.T) {
+	t.Log("Testing newHtpasswdFile with missing file")
+
+	_, err := newHtpasswdFile(filepath.Join(t.TempDir(), "does-not-exist"))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
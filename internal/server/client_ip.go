@@ -0,0 +1,101 @@
+// Copyright © 2017 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/circonus-labs/circonus-agent/internal/config"
+	"github.com/spf13/viper"
+)
+
+type clientIPContextKey struct{}
+
+// trustedProxies holds the CIDR blocks configured via config.KeyTrustedProxies,
+// parsed once at New() time.
+var trustedProxies []*net.IPNet
+
+// initTrustedProxies parses the configured trusted proxy CIDR list. It is
+// called once from New() so every request reuses the parsed networks.
+func initTrustedProxies() error {
+	nets := []*net.IPNet{}
+	for _, cidr := range viper.GetStringSlice(config.KeyTrustedProxies) {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return err
+		}
+		nets = append(nets, ipNet)
+	}
+	trustedProxies = nets
+	return nil
+}
+
+func isTrustedProxy(ip net.IP) bool {
+	for _, n := range trustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveClientIP determines the "real" client IP for a request: it walks
+// X-Forwarded-For right-to-left skipping any hop that falls within a trusted
+// proxy CIDR, falling back to X-Real-IP and finally r.RemoteAddr.
+func resolveClientIP(r *http.Request) string {
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		hops := strings.Split(xff, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			candidate := strings.TrimSpace(hops[i])
+			if candidate == "" {
+				continue
+			}
+			ip := net.ParseIP(candidate)
+			if ip == nil {
+				continue
+			}
+			if isTrustedProxy(ip) {
+				continue
+			}
+			return candidate
+		}
+	}
+
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); realIP != "" {
+		return realIP
+	}
+
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// clientIPMiddleware resolves the caller's IP and attaches it to the request
+// context (retrievable via RequestIP) before calling next.
+func clientIPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := resolveClientIP(r)
+		ctx := context.WithValue(r.Context(), clientIPContextKey{}, ip)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequestIP returns the client IP resolved by clientIPMiddleware for r, or
+// the raw RemoteAddr if the middleware was not applied.
+func RequestIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPContextKey{}).(string); ok {
+		return ip
+	}
+	return r.RemoteAddr
+}
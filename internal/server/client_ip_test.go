@@ -0,0 +1,168 @@
+// Copyright © 2017 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package server
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func setTrustedProxies(t *testing.T, cidrs ...string) {
+	t.Helper()
+	nets := []*net.IPNet{}
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			t.Fatalf("parsing test CIDR %q: %s", cidr, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	orig := trustedProxies
+	trustedProxies = nets
+	t.Cleanup(func() { trustedProxies = orig })
+}
+
+func TestIsTrustedProxy(t *testing.T) {
+	t.Log("Testing isTrustedProxy")
+
+	setTrustedProxies(t, "10.0.0.0/8", "192.168.1.0/24")
+
+	tests := []struct {
+		name   string
+		ip     string
+		expect bool
+	}{
+		{name: "in first range", ip: "10.1.2.3", expect: true},
+		{name: "in second range", ip: "192.168.1.42", expect: true},
+		{name: "outside both ranges", ip: "8.8.8.8", expect: false},
+		{name: "adjacent but outside", ip: "192.168.2.1", expect: false},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			got := isTrustedProxy(net.ParseIP(test.ip))
+			if got != test.expect {
+				t.Fatalf("expected (%v) got (%v)", test.expect, got)
+			}
+		})
+	}
+}
+
+func TestResolveClientIP(t *testing.T) {
+	t.Log("Testing resolveClientIP")
+
+	tests := []struct {
+		name         string
+		trustedCIDRs []string
+		xff          string
+		xRealIP      string
+		remoteAddr   string
+		expect       string
+	}{
+		{
+			name:       "no headers falls back to RemoteAddr host",
+			remoteAddr: "203.0.113.9:54321",
+			expect:     "203.0.113.9",
+		},
+		{
+			name:       "no headers, RemoteAddr without port",
+			remoteAddr: "203.0.113.9",
+			expect:     "203.0.113.9",
+		},
+		{
+			name:       "X-Real-IP used when no XFF",
+			xRealIP:    "198.51.100.7",
+			remoteAddr: "203.0.113.9:54321",
+			expect:     "198.51.100.7",
+		},
+		{
+			name:       "XFF single untrusted hop wins over X-Real-IP",
+			xff:        "198.51.100.1",
+			xRealIP:    "198.51.100.7",
+			remoteAddr: "203.0.113.9:54321",
+			expect:     "198.51.100.1",
+		},
+		{
+			name:         "XFF walks right-to-left past trusted proxies",
+			trustedCIDRs: []string{"10.0.0.0/8"},
+			xff:          "198.51.100.1, 10.0.0.5, 10.0.0.1",
+			remoteAddr:   "10.0.0.1:1234",
+			expect:       "198.51.100.1",
+		},
+		{
+			name:         "all XFF hops trusted falls back to X-Real-IP",
+			trustedCIDRs: []string{"10.0.0.0/8"},
+			xff:          "10.0.0.5, 10.0.0.1",
+			xRealIP:      "198.51.100.7",
+			remoteAddr:   "10.0.0.1:1234",
+			expect:       "198.51.100.7",
+		},
+		{
+			name:       "unparseable XFF hop skipped",
+			xff:        "198.51.100.1, not-an-ip",
+			remoteAddr: "203.0.113.9:54321",
+			expect:     "198.51.100.1",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			if test.trustedCIDRs != nil {
+				setTrustedProxies(t, test.trustedCIDRs...)
+			} else {
+				setTrustedProxies(t)
+			}
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = test.remoteAddr
+			if test.xff != "" {
+				r.Header.Set("X-Forwarded-For", test.xff)
+			}
+			if test.xRealIP != "" {
+				r.Header.Set("X-Real-IP", test.xRealIP)
+			}
+
+			got := resolveClientIP(r)
+			if got != test.expect {
+				t.Fatalf("expected (%s) got (%s)", test.expect, got)
+			}
+		})
+	}
+}
+
+func TestRequestIP(t *testing.T) {
+	t.Log("Testing RequestIP")
+
+	t.Log("without middleware applied")
+	{
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = "203.0.113.9:54321"
+		if got := RequestIP(r); got != r.RemoteAddr {
+			t.Fatalf("expected (%s) got (%s)", r.RemoteAddr, got)
+		}
+	}
+
+	t.Log("with middleware applied")
+	{
+		setTrustedProxies(t)
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = "203.0.113.9:54321"
+
+		var got string
+		h := clientIPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = RequestIP(r)
+		}))
+		h.ServeHTTP(httptest.NewRecorder(), r)
+
+		if got != "203.0.113.9" {
+			t.Fatalf("expected (203.0.113.9) got (%s)", got)
+		}
+	}
+}
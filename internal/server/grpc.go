@@ -0,0 +1,313 @@
+// Copyright © 2017 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/circonus-labs/circonus-agent/internal/config"
+	agentlog "github.com/circonus-labs/circonus-agent/internal/logger"
+	"github.com/circonus-labs/circonus-agent/internal/reverse"
+	"github.com/circonus-labs/circonus-agent/internal/server/pb"
+	"github.com/maier/go-appstats"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// jsonCodec is a stand-in wire codec for pb's hand-maintained message types
+// (see pb/doc.go) until real protobuf-generated types are wired in.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                               { return "json" }
+
+// grpcSrv is the optional gRPC listener built by New(); nil when
+// config.KeyGRPCListen is unset. A package-level handle mirrors the pattern
+// already used for trustedProxies since there is only ever one Server per
+// process.
+var grpcSrv *grpcServer
+
+// grpcServer exposes Plugins/Metrics/Prom/Reverse services alongside the HTTP
+// router. Rather than inventing a second API surface on plugins.Plugins and
+// statsd.Server, each RPC drives the same *Server handler the HTTP router
+// already uses (s.run, s.inventory, s.write, s.promReceiver) through an
+// httptest.ResponseRecorder, so the two transports can never drift apart.
+type grpcServer struct {
+	pb.UnimplementedPluginsServer
+	pb.UnimplementedMetricsServer
+	pb.UnimplementedPromServer
+	pb.UnimplementedReverseServer
+
+	logger zerolog.Logger
+	lsnr   net.Listener
+	srv    *grpc.Server
+	parent *Server
+}
+
+// newGRPCServer builds the optional gRPC listener configured under
+// config.KeyGRPCListen, sharing the HTTPS certificate when one is configured
+// and enforcing the same config.KeyAuthMode as the HTTP listeners. It returns
+// a nil *grpcServer (and nil error) when KeyGRPCListen is unset.
+func newGRPCServer(s *Server) (*grpcServer, error) {
+	addr := viper.GetString(config.KeyGRPCListen)
+	if addr == "" {
+		return nil, nil
+	}
+	if !viper.GetBool(config.KeyGRPCAllowExperimental) {
+		// See config.KeyGRPCAllowExperimental: this listener speaks a private
+		// JSON codec, not real protobuf, until the toolchain to generate one
+		// is wired in. Require an explicit opt-in rather than silently
+		// serving a non-standard "gRPC" API.
+		return nil, errors.Errorf("%s is set but %s is not: this gRPC listener uses a non-standard JSON wire codec, not real protobuf (see internal/server/pb/doc.go) -- set %s=true to acknowledge and start it anyway", config.KeyGRPCListen, config.KeyGRPCAllowExperimental, config.KeyGRPCAllowExperimental)
+	}
+
+	mode := strings.ToLower(viper.GetString(config.KeyAuthMode))
+
+	lsnr, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, errors.Wrap(err, "grpc listen")
+	}
+
+	opts := []grpc.ServerOption{
+		grpc.ForceServerCodec(jsonCodec{}),
+		grpc.UnaryInterceptor(authUnaryInterceptor(mode)),
+		grpc.StreamInterceptor(authStreamInterceptor(mode)),
+	}
+
+	if s.svrHTTPS != nil {
+		// Load the cert/key pair directly rather than cloning svrHTTPS.TLSConfig:
+		// that config either has no Certificates at all (plain https) or, in
+		// mtls mode, only ClientAuth/ClientCAs (mtlsClientConfig never sets a
+		// server certificate -- http.Server.ListenAndServeTLS loads cert/key
+		// itself, later, from disk). Reusing it as-is would leave gRPC with no
+		// certificate and every TLS handshake failing.
+		certFile := viper.GetString(config.KeySSLCertFile)
+		keyFile := viper.GetString(config.KeySSLKeyFile)
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "loading grpc tls certificate")
+		}
+
+		tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}}
+		if s.svrHTTPS.TLSConfig != nil {
+			// mtls mode: require/verify client certs on the gRPC listener too.
+			tlsConfig.ClientAuth = s.svrHTTPS.TLSConfig.ClientAuth
+			tlsConfig.ClientCAs = s.svrHTTPS.TLSConfig.ClientCAs
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	} else if mode == authModeMTLS {
+		// Without an HTTPS listener there is no certificate to share and
+		// nothing to terminate TLS on this listener at all, so mtls -- whose
+		// enforcement lives entirely in the TLS handshake -- can't be
+		// honored; refuse to start rather than serve gRPC in the clear.
+		return nil, errors.Errorf("%s requires %s when %s is %q", config.KeyGRPCListen, config.KeySSLListen, config.KeyAuthMode, authModeMTLS)
+	}
+
+	g := &grpcServer{
+		logger: agentlog.For("server.grpc"),
+		lsnr:   lsnr,
+		srv:    grpc.NewServer(opts...),
+		parent: s,
+	}
+
+	pb.RegisterPluginsServer(g.srv, g)
+	pb.RegisterMetricsServer(g.srv, g)
+	pb.RegisterPromServer(g.srv, g)
+	pb.RegisterReverseServer(g.srv, g)
+
+	return g, nil
+}
+
+// authUnaryInterceptor enforces mode (config.KeyAuthMode) on every unary RPC,
+// mirroring authMiddleware's handling of the HTTP listeners.
+func authUnaryInterceptor(mode string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := checkGRPCAuth(ctx, mode); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// authStreamInterceptor is authUnaryInterceptor's counterpart for the
+// Metrics.Write and Reverse.Watch streaming RPCs.
+func authStreamInterceptor(mode string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := checkGRPCAuth(ss.Context(), mode); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// checkGRPCAuth authenticates an incoming RPC against mode. mtls needs no
+// check here: tls.Config.ClientAuth already rejected the connection during
+// the TLS handshake (see newGRPCServer) before any RPC could reach this code.
+func checkGRPCAuth(ctx context.Context, mode string) error {
+	switch mode {
+	case "", authModeNone, authModeMTLS:
+		return nil
+	case authModeBasic:
+		if grpcAuthenticator == nil {
+			return status.Error(codes.Internal, "basic auth not configured")
+		}
+		md, ok := metadata.FromIncomingContext(ctx)
+		if ok {
+			if user, pass, ok := basicAuthFromMetadata(md); ok && grpcAuthenticator(user, pass) {
+				return nil
+			}
+		}
+		_ = appstats.IncrementInt("requests_unauthorized")
+		return status.Error(codes.Unauthenticated, "invalid credentials")
+	default:
+		return status.Errorf(codes.Internal, "unknown auth mode %q", mode)
+	}
+}
+
+// basicAuthFromMetadata extracts user/pass from a gRPC "authorization"
+// metadata entry formatted the same way as the HTTP Authorization header:
+// "Basic " + base64(user:pass).
+func basicAuthFromMetadata(md metadata.MD) (string, string, bool) {
+	vals := md.Get("authorization")
+	if len(vals) == 0 {
+		return "", "", false
+	}
+	const prefix = "Basic "
+	if !strings.HasPrefix(vals[0], prefix) {
+		return "", "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(vals[0][len(prefix):])
+	if err != nil {
+		return "", "", false
+	}
+	parts := strings.SplitN(string(decoded), ":", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+func (g *grpcServer) start() error {
+	if g == nil {
+		return nil
+	}
+	g.logger.Info().Str("listen", g.lsnr.Addr().String()).Msg("Starting gRPC")
+	if err := g.srv.Serve(g.lsnr); err != nil {
+		return errors.Wrap(err, "gRPC server")
+	}
+	return nil
+}
+
+func (g *grpcServer) stop() {
+	if g == nil {
+		return
+	}
+	g.logger.Info().Msg("Stopping gRPC server")
+	g.srv.GracefulStop()
+}
+
+// Run implements Plugins.Run by driving the parent Server's existing /run
+// handler, the same one the HTTP router dispatches to.
+func (g *grpcServer) Run(ctx context.Context, req *pb.RunRequest) (*pb.RunResponse, error) {
+	path := "/run/" + req.GetPluginName()
+	r := httptest.NewRequest(http.MethodGet, path, nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	g.parent.run(w, r)
+	if w.Code >= http.StatusBadRequest {
+		return nil, status.Error(codes.Internal, w.Body.String())
+	}
+	return &pb.RunResponse{ResultsJson: w.Body.Bytes()}, nil
+}
+
+// Inventory implements Plugins.Inventory by driving the parent Server's
+// existing /inventory handler.
+func (g *grpcServer) Inventory(ctx context.Context, _ *pb.Empty) (*pb.InventoryResponse, error) {
+	w := httptest.NewRecorder()
+	g.parent.inventory(w)
+	if w.Code >= http.StatusBadRequest {
+		return nil, status.Error(codes.Internal, w.Body.String())
+	}
+	return &pb.InventoryResponse{InventoryJson: w.Body.Bytes()}, nil
+}
+
+// Write implements Metrics.Write, a client stream for high-frequency writers,
+// by driving the parent Server's existing /write handler once per metric.
+func (g *grpcServer) Write(stream pb.Metrics_WriteServer) error {
+	var accepted uint32
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(&pb.WriteSummary{Accepted: accepted})
+		}
+		if err != nil {
+			return errors.Wrap(err, "receiving metric")
+		}
+
+		r := httptest.NewRequest(http.MethodPut, "/write", bytes.NewReader(req.GetMetricJson())).WithContext(stream.Context())
+		w := httptest.NewRecorder()
+		g.parent.write(w, r)
+		if w.Code >= http.StatusBadRequest {
+			return status.Error(codes.Internal, w.Body.String())
+		}
+		accepted++
+	}
+}
+
+// Push implements Prom.Push by driving the parent Server's existing /prom
+// receiver handler.
+func (g *grpcServer) Push(ctx context.Context, req *pb.PromPushRequest) (*pb.Empty, error) {
+	path := "/prom/" + req.GetTarget()
+	r := httptest.NewRequest(http.MethodPut, path, bytes.NewReader(req.GetMetricsText())).WithContext(ctx)
+	w := httptest.NewRecorder()
+	g.parent.promReceiver(w, r)
+	if w.Code >= http.StatusBadRequest {
+		return nil, status.Error(codes.Internal, w.Body.String())
+	}
+	return &pb.Empty{}, nil
+}
+
+// Watch implements Reverse.Watch, streaming the {state, attempt, last_error}
+// events emitted by the reverse-connection goroutine started in reverse.Start
+// until the caller disconnects.
+func (g *grpcServer) Watch(_ *pb.Empty, stream pb.Reverse_WatchServer) error {
+	events := reverse.Subscribe()
+	defer reverse.Unsubscribe(events)
+
+	for {
+		select {
+		case evt, ok := <-events:
+			if !ok {
+				return nil
+			}
+			out := &pb.ReverseEvent{
+				State:     string(evt.State),
+				Attempt:   int32(evt.Attempt),
+				LastError: evt.LastError,
+			}
+			if err := stream.Send(out); err != nil {
+				return errors.Wrap(err, "sending reverse event")
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
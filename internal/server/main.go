@@ -6,13 +6,17 @@
 package server
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
 	"net/http"
+	"strings"
 
 	"github.com/circonus-labs/circonus-agent/internal/config"
+	agentlog "github.com/circonus-labs/circonus-agent/internal/logger"
 	"github.com/circonus-labs/circonus-agent/internal/plugins"
 	"github.com/circonus-labs/circonus-agent/internal/statsd"
 	"github.com/pkg/errors"
-	"github.com/rs/zerolog/log"
 	"github.com/spf13/viper"
 	"xi2.org/x/httpgzip"
 )
@@ -20,14 +24,30 @@ import (
 // New creates a new instance of the listening servers
 func New(p *plugins.Plugins, ss *statsd.Server) (*Server, error) {
 	s := Server{
-		logger:    log.With().Str("pkg", "server").Logger(),
+		logger:    agentlog.For("server"),
 		plugins:   p,
 		statsdSvr: ss,
 	}
 
-	gzipHandler := httpgzip.NewHandler(http.HandlerFunc(s.router), []string{"application/json"})
+	if err := initTrustedProxies(); err != nil {
+		return nil, errors.Wrap(err, "parsing trusted proxies")
+	}
+
+	authedRouter, err := authMiddleware(http.HandlerFunc(s.router))
+	if err != nil {
+		return nil, errors.Wrap(err, "configuring auth")
+	}
+	gzipHandler := httpgzip.NewHandler(clientIPMiddleware(authedRouter), []string{"application/json"})
+
+	mtlsMode := strings.ToLower(viper.GetString(config.KeyAuthMode)) == authModeMTLS
 
 	if addr := viper.GetString(config.KeyListen); addr != "" {
+		if mtlsMode {
+			// mtls enforcement lives entirely in svrHTTPS.TLSConfig.ClientAuth;
+			// authMiddleware is a no-op in this mode (see auth.go), so a plain
+			// HTTP listener left running would bypass it completely.
+			return nil, errors.Errorf("%s cannot be set when %s is %q: disable it or switch auth modes", config.KeyListen, config.KeyAuthMode, authModeMTLS)
+		}
 		s.svrHTTP = &http.Server{Addr: addr, Handler: gzipHandler}
 		s.svrHTTP.SetKeepAlivesEnabled(false)
 	}
@@ -35,19 +55,58 @@ func New(p *plugins.Plugins, ss *statsd.Server) (*Server, error) {
 	if addr := viper.GetString(config.KeySSLListen); addr != "" {
 		s.svrHTTPS = &http.Server{Addr: addr, Handler: gzipHandler}
 		s.svrHTTPS.SetKeepAlivesEnabled(false)
+
+		if strings.ToLower(viper.GetString(config.KeyAuthMode)) == authModeMTLS {
+			tlsConfig, err := mtlsClientConfig()
+			if err != nil {
+				return nil, errors.Wrap(err, "configuring mtls")
+			}
+			s.svrHTTPS.TLSConfig = tlsConfig
+		}
+	}
+
+	g, err := newGRPCServer(&s)
+	if err != nil {
+		return nil, errors.Wrap(err, "configuring grpc")
 	}
+	grpcSrv = g
 
 	return &s, nil
 }
 
+// mtlsClientConfig builds a tls.Config requiring and verifying a client
+// certificate signed by the configured CA bundle.
+func mtlsClientConfig() (*tls.Config, error) {
+	caFile := viper.GetString(config.KeyAuthMTLSCAFile)
+	if caFile == "" {
+		return nil, errors.Errorf("auth mode %q requires %s", authModeMTLS, config.KeyAuthMTLSCAFile)
+	}
+
+	caPEM, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading mtls ca file")
+	}
+
+	pool := x509.NewCertPool()
+	if ok := pool.AppendCertsFromPEM(caPEM); !ok {
+		return nil, errors.Errorf("no valid certificates found in %s", caFile)
+	}
+
+	return &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  pool,
+	}, nil
+}
+
 // Start main listening server(s)
 func (s *Server) Start() error {
-	if s.svrHTTP == nil && s.svrHTTPS == nil {
+	if s.svrHTTP == nil && s.svrHTTPS == nil && grpcSrv == nil {
 		return errors.New("No servers defined")
 	}
 
 	s.t.Go(s.startHTTP)
 	s.t.Go(s.startHTTPS)
+	s.t.Go(grpcSrv.start)
 
 	return s.t.Wait()
 }
@@ -70,6 +129,8 @@ func (s *Server) Stop() {
 		}
 	}
 
+	grpcSrv.stop()
+
 	if s.t.Alive() {
 		s.t.Kill(nil)
 	}
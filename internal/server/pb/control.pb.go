@@ -0,0 +1,123 @@
+// Copyright © 2017 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// Code generated from control.proto. Hand-maintained until protoc/
+// protoc-gen-go/protoc-gen-go-grpc are wired into this repo's build; see
+// doc.go. Field names/shapes must stay in sync with control.proto.
+
+package pb
+
+// Empty is an RPC request/response with no fields.
+type Empty struct{}
+
+// RunRequest selects which plugin(s) to run; PluginName == "" runs every
+// plugin, matching the HTTP /run and /run/{plugin} semantics.
+type RunRequest struct {
+	PluginName string `json:"plugin_name,omitempty"`
+}
+
+func (m *RunRequest) GetPluginName() string {
+	if m != nil {
+		return m.PluginName
+	}
+	return ""
+}
+
+// RunResponse carries the same JSON payload the HTTP /run endpoint returns.
+type RunResponse struct {
+	ResultsJson []byte `json:"results_json,omitempty"`
+}
+
+func (m *RunResponse) GetResultsJson() []byte {
+	if m != nil {
+		return m.ResultsJson
+	}
+	return nil
+}
+
+// InventoryResponse carries the same JSON payload the HTTP /inventory
+// endpoint returns.
+type InventoryResponse struct {
+	InventoryJson []byte `json:"inventory_json,omitempty"`
+}
+
+func (m *InventoryResponse) GetInventoryJson() []byte {
+	if m != nil {
+		return m.InventoryJson
+	}
+	return nil
+}
+
+// WriteRequest is a single metric, JSON encoded the same way the HTTP
+// /write receiver expects its body.
+type WriteRequest struct {
+	MetricJson []byte `json:"metric_json,omitempty"`
+}
+
+func (m *WriteRequest) GetMetricJson() []byte {
+	if m != nil {
+		return m.MetricJson
+	}
+	return nil
+}
+
+// WriteSummary reports how many metrics a Metrics.Write stream accepted.
+type WriteSummary struct {
+	Accepted uint32 `json:"accepted,omitempty"`
+}
+
+func (m *WriteSummary) GetAccepted() uint32 {
+	if m != nil {
+		return m.Accepted
+	}
+	return 0
+}
+
+// PromPushRequest mirrors the HTTP /prom receiver body plus its target path segment.
+type PromPushRequest struct {
+	Target      string `json:"target,omitempty"`
+	MetricsText []byte `json:"metrics_text,omitempty"`
+}
+
+func (m *PromPushRequest) GetTarget() string {
+	if m != nil {
+		return m.Target
+	}
+	return ""
+}
+
+func (m *PromPushRequest) GetMetricsText() []byte {
+	if m != nil {
+		return m.MetricsText
+	}
+	return nil
+}
+
+// ReverseEvent is a single reverse-connection state transition.
+type ReverseEvent struct {
+	State     string `json:"state,omitempty"`
+	Attempt   int32  `json:"attempt,omitempty"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+func (m *ReverseEvent) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
+}
+
+func (m *ReverseEvent) GetAttempt() int32 {
+	if m != nil {
+		return m.Attempt
+	}
+	return 0
+}
+
+func (m *ReverseEvent) GetLastError() string {
+	if m != nil {
+		return m.LastError
+	}
+	return ""
+}
@@ -0,0 +1,382 @@
+// Copyright © 2017 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// Code generated from control.proto. Hand-maintained until protoc/
+// protoc-gen-go/protoc-gen-go-grpc are wired into this repo's build; see
+// doc.go. Mirrors the client/server stubs protoc-gen-go-grpc would produce
+// for the Plugins/Metrics/Prom/Reverse services.
+
+package pb
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ------------------------------------------------------------------------
+// Plugins
+// ------------------------------------------------------------------------
+
+type PluginsClient interface {
+	Run(ctx context.Context, in *RunRequest, opts ...grpc.CallOption) (*RunResponse, error)
+	Inventory(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*InventoryResponse, error)
+}
+
+type pluginsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPluginsClient(cc grpc.ClientConnInterface) PluginsClient {
+	return &pluginsClient{cc}
+}
+
+func (c *pluginsClient) Run(ctx context.Context, in *RunRequest, opts ...grpc.CallOption) (*RunResponse, error) {
+	out := new(RunResponse)
+	if err := c.cc.Invoke(ctx, "/circonus.agent.control.v1.Plugins/Run", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginsClient) Inventory(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*InventoryResponse, error) {
+	out := new(InventoryResponse)
+	if err := c.cc.Invoke(ctx, "/circonus.agent.control.v1.Plugins/Inventory", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type PluginsServer interface {
+	Run(context.Context, *RunRequest) (*RunResponse, error)
+	Inventory(context.Context, *Empty) (*InventoryResponse, error)
+	mustEmbedUnimplementedPluginsServer()
+}
+
+type UnimplementedPluginsServer struct{}
+
+func (UnimplementedPluginsServer) Run(context.Context, *RunRequest) (*RunResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Run not implemented")
+}
+
+func (UnimplementedPluginsServer) Inventory(context.Context, *Empty) (*InventoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Inventory not implemented")
+}
+
+func (UnimplementedPluginsServer) mustEmbedUnimplementedPluginsServer() {}
+
+func RegisterPluginsServer(s grpc.ServiceRegistrar, srv PluginsServer) {
+	s.RegisterService(&Plugins_ServiceDesc, srv)
+}
+
+func _Plugins_Run_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginsServer).Run(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/circonus.agent.control.v1.Plugins/Run"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginsServer).Run(ctx, req.(*RunRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Plugins_Inventory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginsServer).Inventory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/circonus.agent.control.v1.Plugins/Inventory"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginsServer).Inventory(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var Plugins_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "circonus.agent.control.v1.Plugins",
+	HandlerType: (*PluginsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Run", Handler: _Plugins_Run_Handler},
+		{MethodName: "Inventory", Handler: _Plugins_Inventory_Handler},
+	},
+	Metadata: "internal/server/pb/control.proto",
+}
+
+// ------------------------------------------------------------------------
+// Metrics
+// ------------------------------------------------------------------------
+
+type MetricsClient interface {
+	Write(ctx context.Context, opts ...grpc.CallOption) (Metrics_WriteClient, error)
+}
+
+type metricsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMetricsClient(cc grpc.ClientConnInterface) MetricsClient {
+	return &metricsClient{cc}
+}
+
+func (c *metricsClient) Write(ctx context.Context, opts ...grpc.CallOption) (Metrics_WriteClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Metrics_ServiceDesc.Streams[0], "/circonus.agent.control.v1.Metrics/Write", opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &metricsWriteClient{stream}, nil
+}
+
+type Metrics_WriteClient interface {
+	Send(*WriteRequest) error
+	CloseAndRecv() (*WriteSummary, error)
+	grpc.ClientStream
+}
+
+type metricsWriteClient struct {
+	grpc.ClientStream
+}
+
+func (x *metricsWriteClient) Send(m *WriteRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *metricsWriteClient) CloseAndRecv() (*WriteSummary, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(WriteSummary)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+type MetricsServer interface {
+	Write(Metrics_WriteServer) error
+	mustEmbedUnimplementedMetricsServer()
+}
+
+type UnimplementedMetricsServer struct{}
+
+func (UnimplementedMetricsServer) Write(Metrics_WriteServer) error {
+	return status.Errorf(codes.Unimplemented, "method Write not implemented")
+}
+
+func (UnimplementedMetricsServer) mustEmbedUnimplementedMetricsServer() {}
+
+type Metrics_WriteServer interface {
+	SendAndClose(*WriteSummary) error
+	Recv() (*WriteRequest, error)
+	grpc.ServerStream
+}
+
+type metricsWriteServer struct {
+	grpc.ServerStream
+}
+
+func (x *metricsWriteServer) SendAndClose(m *WriteSummary) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *metricsWriteServer) Recv() (*WriteRequest, error) {
+	m := new(WriteRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func RegisterMetricsServer(s grpc.ServiceRegistrar, srv MetricsServer) {
+	s.RegisterService(&Metrics_ServiceDesc, srv)
+}
+
+func _Metrics_Write_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MetricsServer).Write(&metricsWriteServer{stream})
+}
+
+var Metrics_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "circonus.agent.control.v1.Metrics",
+	HandlerType: (*MetricsServer)(nil),
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Write",
+			Handler:       _Metrics_Write_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "internal/server/pb/control.proto",
+}
+
+// ------------------------------------------------------------------------
+// Prom
+// ------------------------------------------------------------------------
+
+type PromClient interface {
+	Push(ctx context.Context, in *PromPushRequest, opts ...grpc.CallOption) (*Empty, error)
+}
+
+type promClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPromClient(cc grpc.ClientConnInterface) PromClient {
+	return &promClient{cc}
+}
+
+func (c *promClient) Push(ctx context.Context, in *PromPushRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	if err := c.cc.Invoke(ctx, "/circonus.agent.control.v1.Prom/Push", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type PromServer interface {
+	Push(context.Context, *PromPushRequest) (*Empty, error)
+	mustEmbedUnimplementedPromServer()
+}
+
+type UnimplementedPromServer struct{}
+
+func (UnimplementedPromServer) Push(context.Context, *PromPushRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Push not implemented")
+}
+
+func (UnimplementedPromServer) mustEmbedUnimplementedPromServer() {}
+
+func RegisterPromServer(s grpc.ServiceRegistrar, srv PromServer) {
+	s.RegisterService(&Prom_ServiceDesc, srv)
+}
+
+func _Prom_Push_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PromPushRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PromServer).Push(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/circonus.agent.control.v1.Prom/Push"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PromServer).Push(ctx, req.(*PromPushRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var Prom_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "circonus.agent.control.v1.Prom",
+	HandlerType: (*PromServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Push", Handler: _Prom_Push_Handler},
+	},
+	Metadata: "internal/server/pb/control.proto",
+}
+
+// ------------------------------------------------------------------------
+// Reverse
+// ------------------------------------------------------------------------
+
+type ReverseClient interface {
+	Watch(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Reverse_WatchClient, error)
+}
+
+type reverseClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewReverseClient(cc grpc.ClientConnInterface) ReverseClient {
+	return &reverseClient{cc}
+}
+
+func (c *reverseClient) Watch(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Reverse_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Reverse_ServiceDesc.Streams[0], "/circonus.agent.control.v1.Reverse/Watch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &reverseWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Reverse_WatchClient interface {
+	Recv() (*ReverseEvent, error)
+	grpc.ClientStream
+}
+
+type reverseWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *reverseWatchClient) Recv() (*ReverseEvent, error) {
+	m := new(ReverseEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+type ReverseServer interface {
+	Watch(*Empty, Reverse_WatchServer) error
+	mustEmbedUnimplementedReverseServer()
+}
+
+type UnimplementedReverseServer struct{}
+
+func (UnimplementedReverseServer) Watch(*Empty, Reverse_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+
+func (UnimplementedReverseServer) mustEmbedUnimplementedReverseServer() {}
+
+type Reverse_WatchServer interface {
+	Send(*ReverseEvent) error
+	grpc.ServerStream
+}
+
+type reverseWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *reverseWatchServer) Send(m *ReverseEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func RegisterReverseServer(s grpc.ServiceRegistrar, srv ReverseServer) {
+	s.RegisterService(&Reverse_ServiceDesc, srv)
+}
+
+func _Reverse_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ReverseServer).Watch(m, &reverseWatchServer{stream})
+}
+
+var Reverse_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "circonus.agent.control.v1.Reverse",
+	HandlerType: (*ReverseServer)(nil),
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _Reverse_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "internal/server/pb/control.proto",
+}
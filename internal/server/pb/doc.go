@@ -0,0 +1,14 @@
+// Copyright © 2017 Circonus, Inc. <support@circonus.com>
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+
+// Package pb holds the message and service types described by
+// control.proto. protoc/protoc-gen-go/protoc-gen-go-grpc are not yet wired
+// into this repo's build, so control.pb.go and control_grpc.pb.go are
+// hand-maintained to match what they would generate; keep their field
+// names/shapes in sync with control.proto when it changes. Because there is
+// no real protobuf marshaler behind these types yet, the gRPC server
+// (internal/server/grpc.go) is configured with a JSON wire codec rather than
+// the default protobuf one -- swap that out once the real toolchain lands.
+package pb
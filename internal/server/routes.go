@@ -15,7 +15,7 @@ import (
 func (s *Server) router(w http.ResponseWriter, r *http.Request) {
 	_ = appstats.IncrementInt("requests_total")
 
-	s.logger.Debug().Str("method", r.Method).Str("url", r.URL.String()).Msg("request")
+	s.logger.Debug().Str("method", r.Method).Str("url", r.URL.String()).Str("client_ip", RequestIP(r)).Msg("request")
 
 	switch r.Method {
 	case "GET":
@@ -30,7 +30,7 @@ func (s *Server) router(w http.ResponseWriter, r *http.Request) {
 			s.promOutput(w)
 		default:
 			_ = appstats.IncrementInt("requests_bad")
-			s.logger.Warn().Str("method", r.Method).Str("url", r.URL.String()).Msg("not found")
+			s.logger.Warn().Str("method", r.Method).Str("url", r.URL.String()).Str("client_ip", RequestIP(r)).Msg("not found")
 			http.NotFound(w, r)
 		}
 	case "POST":
@@ -43,11 +43,12 @@ func (s *Server) router(w http.ResponseWriter, r *http.Request) {
 			s.promReceiver(w, r)
 		default:
 			_ = appstats.IncrementInt("requests_bad")
-			s.logger.Warn().Str("method", r.Method).Str("url", r.URL.String()).Msg("not found")
+			s.logger.Warn().Str("method", r.Method).Str("url", r.URL.String()).Str("client_ip", RequestIP(r)).Msg("not found")
 			http.NotFound(w, r)
 		}
 	default:
 		_ = appstats.IncrementInt("requests_bad")
+		s.logger.Warn().Str("method", r.Method).Str("url", r.URL.String()).Str("client_ip", RequestIP(r)).Msg("method not allowed")
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 	}
 }